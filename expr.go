@@ -0,0 +1,228 @@
+package feature
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// RegisterExpr defines name as a boolean expression over other features
+// and simple ctx/env predicates, instead of a plain on/off toggle. The
+// grammar supports and, or, not, parentheses, other feature names, and
+// predicates of the form:
+//
+//	header:tier == "beta"
+//	env:REGION in ("us-east", "us-west")
+//
+// e.g. RegisterExpr("new-checkout", `paginate and not legacy-cart`).
+//
+// IsEnabled(ctx, name) then evaluates the expression using ctx, so feature
+// values captured by ReqWithFeatureCtx from headers and query parameters
+// apply to referenced features, and the raw request headers it captures
+// are readable by header: predicates. Query parameters are not readable
+// by predicates. RegisterExpr rejects expressions that would introduce a
+// cycle through other registered expressions.
+func RegisterExpr(name Feature, expr string) error {
+	name = Feature(strings.ToLower(name.String()))
+
+	node, err := parseExpr(expr)
+	if err != nil {
+		return fmt.Errorf("feature: RegisterExpr %q: %w", name, err)
+	}
+
+	exprMu.Lock()
+	defer exprMu.Unlock()
+
+	prev, hadPrev := exprs[name]
+	exprs[name] = exprEntry{node: node, raw: expr}
+
+	if err := validateLocked(); err != nil {
+		if hadPrev {
+			exprs[name] = prev
+		} else {
+			delete(exprs, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// Validate reports an error if any expression registered via RegisterExpr
+// forms a dependency cycle with another.
+func Validate() error {
+	exprMu.RLock()
+	defer exprMu.RUnlock()
+	return validateLocked()
+}
+
+// exprEntry pairs a parsed expression with the raw text RegisterExpr was
+// given, so List can surface it for introspection.
+type exprEntry struct {
+	node exprNode
+	raw  string
+}
+
+var (
+	exprMu sync.RWMutex
+	exprs  = make(map[Feature]exprEntry)
+)
+
+func evalExpr(ctx context.Context, name Feature) (bool, bool) {
+	exprMu.RLock()
+	e, ok := exprs[name]
+	exprMu.RUnlock()
+	if !ok {
+		return false, false
+	}
+	node := e.node
+	return node.eval(ctx), true
+}
+
+const (
+	colorWhite = iota
+	colorGray
+	colorBlack
+)
+
+func validateLocked() error {
+	color := make(map[Feature]int, len(exprs))
+
+	var visit func(name Feature, path []Feature) error
+	visit = func(name Feature, path []Feature) error {
+		switch color[name] {
+		case colorBlack:
+			return nil
+		case colorGray:
+			return fmt.Errorf("feature: RegisterExpr: cycle detected: %s -> %s", joinFeatures(path), name)
+		}
+
+		color[name] = colorGray
+		deps := make(map[Feature]bool)
+		exprs[name].node.deps(deps)
+		for dep := range deps {
+			if _, ok := exprs[dep]; !ok {
+				continue // not an expression, so it can't be part of a cycle
+			}
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		color[name] = colorBlack
+		return nil
+	}
+
+	for name := range exprs {
+		if color[name] == colorWhite {
+			if err := visit(name, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func joinFeatures(path []Feature) string {
+	names := make([]string, len(path))
+	for i, f := range path {
+		names[i] = f.String()
+	}
+	return strings.Join(names, " -> ")
+}
+
+// exprNode is one node of a parsed RegisterExpr boolean expression.
+type exprNode interface {
+	eval(ctx context.Context) bool
+	deps(out map[Feature]bool)
+}
+
+type featureRefNode struct{ name Feature }
+
+// eval uses resolveEnabled rather than IsEnabled so that evaluating a
+// composed feature doesn't also fire hooks/bump Metrics for every
+// feature it references - only the top-level IsEnabled call does that.
+func (n featureRefNode) eval(ctx context.Context) bool {
+	enabled, _ := resolveEnabled(ctx, n.name)
+	return enabled
+}
+func (n featureRefNode) deps(out map[Feature]bool) { out[n.name] = true }
+
+type notNode struct{ x exprNode }
+
+func (n notNode) eval(ctx context.Context) bool { return !n.x.eval(ctx) }
+func (n notNode) deps(out map[Feature]bool)     { n.x.deps(out) }
+
+type andNode struct{ a, b exprNode }
+
+func (n andNode) eval(ctx context.Context) bool { return n.a.eval(ctx) && n.b.eval(ctx) }
+func (n andNode) deps(out map[Feature]bool) {
+	n.a.deps(out)
+	n.b.deps(out)
+}
+
+type orNode struct{ a, b exprNode }
+
+func (n orNode) eval(ctx context.Context) bool { return n.a.eval(ctx) || n.b.eval(ctx) }
+func (n orNode) deps(out map[Feature]bool) {
+	n.a.deps(out)
+	n.b.deps(out)
+}
+
+// predicateNode is a leaf like `header:tier == "beta"` or
+// `env:REGION in ("us-east", "us-west")`.
+type predicateNode struct {
+	scope  string // "header" or "env"
+	field  string
+	negate bool // true for "!="
+	in     bool // true for "in (...)"
+	values []string
+}
+
+func (n predicateNode) deps(map[Feature]bool) {}
+
+func (n predicateNode) eval(ctx context.Context) bool {
+	// A missing header/env value compares as "". That makes
+	// header:tier != "beta" true when there's no tier header at all,
+	// which is the intended "!=" semantics, rather than unconditionally
+	// false just because the value was absent.
+	actual, _ := n.lookup(ctx)
+
+	if n.in {
+		for _, v := range n.values {
+			if actual == v {
+				return true
+			}
+		}
+		return false
+	}
+
+	match := len(n.values) == 1 && actual == n.values[0]
+	if n.negate {
+		return !match
+	}
+	return match
+}
+
+func (n predicateNode) lookup(ctx context.Context) (string, bool) {
+	switch n.scope {
+	case "header":
+		headers, ok := ctx.Value(headerValuesCtxKey{}).(http.Header)
+		if !ok {
+			return "", false
+		}
+		v := headers.Get(n.field)
+		return v, v != ""
+	case "env":
+		v, ok := os.LookupEnv(n.field)
+		return v, ok
+	default:
+		return "", false
+	}
+}
+
+// headerValuesCtxKey is the ctx key under which ReqWithFeatureCtx stashes
+// the raw request headers, so header: predicates can read values that
+// fromValues only distilled down to a bool/percent.
+type headerValuesCtxKey struct{}
@@ -0,0 +1,111 @@
+package feature_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tempcke/feature"
+)
+
+func TestRegisterExpr_BooleanLogic(t *testing.T) {
+	var (
+		a        = feature.Feature(uuid.NewString())
+		b        = feature.Feature(uuid.NewString())
+		composed = feature.Feature(uuid.NewString())
+	)
+	require.NoError(t, feature.RegisterExpr(composed, a.String()+" and not "+b.String()))
+
+	cases := []struct {
+		aEnabled, bEnabled, want bool
+	}{
+		{true, false, true},
+		{true, true, false},
+		{false, false, false},
+		{false, true, false},
+	}
+	for _, tc := range cases {
+		feature.Enable(a)
+		feature.Disable(a)
+		if tc.aEnabled {
+			feature.Enable(a)
+		}
+		if tc.bEnabled {
+			feature.Enable(b)
+		} else {
+			feature.Disable(b)
+		}
+		if !tc.aEnabled {
+			feature.Disable(a)
+		}
+
+		assert.Equal(t, tc.want, feature.IsEnabled(context.Background(), composed))
+	}
+}
+
+func TestRegisterExpr_HeaderPredicate(t *testing.T) {
+	var name = feature.Feature(uuid.NewString())
+	require.NoError(t, feature.RegisterExpr(name, `header:tier == "beta"`))
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	require.NoError(t, err)
+	req.Header.Set("tier", "beta")
+	req = feature.ReqWithFeatureCtx(req)
+
+	assert.True(t, feature.IsEnabled(req.Context(), name))
+}
+
+func TestRegisterExpr_HeaderPredicateNotEqualsOnMissingHeader(t *testing.T) {
+	var name = feature.Feature(uuid.NewString())
+	require.NoError(t, feature.RegisterExpr(name, `header:tier != "beta"`))
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	require.NoError(t, err)
+	req = feature.ReqWithFeatureCtx(req) // no tier header set at all
+
+	assert.True(t, feature.IsEnabled(req.Context(), name),
+		"a missing header should compare as \"\", so != \"beta\" should be true")
+}
+
+func TestRegisterExpr_EnvPredicateIn(t *testing.T) {
+	var name = feature.Feature(uuid.NewString())
+	require.NoError(t, feature.RegisterExpr(name, `env:REGION in ("us-east", "us-west")`))
+
+	t.Setenv("REGION", "us-west")
+	assert.True(t, feature.IsEnabled(context.Background(), name))
+
+	t.Setenv("REGION", "eu-central")
+	assert.False(t, feature.IsEnabled(context.Background(), name))
+}
+
+func TestRegisterExpr_RejectsCycle(t *testing.T) {
+	var (
+		a = feature.Feature(uuid.NewString())
+		b = feature.Feature(uuid.NewString())
+	)
+
+	require.NoError(t, feature.RegisterExpr(a, b.String()))
+	err := feature.RegisterExpr(b, a.String())
+	assert.Error(t, err, "b depending on a, which already depends on b, is a cycle")
+
+	assert.NoError(t, feature.Validate(), "the rejected registration must not have been kept")
+}
+
+func TestList_IncludesExprFeatures(t *testing.T) {
+	name := feature.Feature(uuid.NewString())
+	require.NoError(t, feature.RegisterExpr(name, "not "+uuid.NewString()))
+
+	var found *feature.FeatureInfo
+	for _, info := range feature.List() {
+		info := info
+		if info.Name == name {
+			found = &info
+		}
+	}
+
+	require.NotNil(t, found, "List should surface features defined via RegisterExpr")
+	assert.NotEmpty(t, found.Expr)
+}
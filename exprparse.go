@@ -0,0 +1,272 @@
+package feature
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseExpr parses the grammar documented on RegisterExpr:
+//
+//	expr   := orExpr
+//	orExpr := andExpr ("or" andExpr)*
+//	andExpr:= unary ("and" unary)*
+//	unary  := "not" unary | atom
+//	atom   := "(" expr ")" | predicate | IDENT
+//	predicate := scope ":" IDENT ("==" | "!=") STRING
+//	           | scope ":" IDENT "in" "(" STRING ("," STRING)* ")"
+//	scope  := "header" | "env"
+func parseExpr(s string) (exprNode, error) {
+	toks, err := lexExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, fmt.Errorf("unexpected token %q", p.peek().text)
+	}
+	return node, nil
+}
+
+type exprParser struct {
+	toks []exprToken
+	pos  int
+}
+
+func (p *exprParser) peek() exprToken {
+	if p.pos >= len(p.toks) {
+		return exprToken{kind: tokEOF}
+	}
+	return p.toks[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *exprParser) expect(kind exprTokenKind, text string) error {
+	t := p.next()
+	if t.kind != kind {
+		return fmt.Errorf("expected %s, got %q", text, t.text)
+	}
+	return nil
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{a: left, b: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{a: left, b: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.peek().kind == tokNot {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x: x}, nil
+	}
+	return p.parseAtom()
+}
+
+func (p *exprParser) parseAtom() (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case tokIdent:
+		if p.peek().kind == tokColon {
+			return p.parsePredicate(t.text)
+		}
+		return featureRefNode{name: Feature(strings.ToLower(t.text))}, nil
+	default:
+		return nil, fmt.Errorf("unexpected token %q", t.text)
+	}
+}
+
+func (p *exprParser) parsePredicate(scope string) (exprNode, error) {
+	if scope != "header" && scope != "env" {
+		return nil, fmt.Errorf("unknown predicate scope %q, expected header or env", scope)
+	}
+	if err := p.expect(tokColon, ":"); err != nil {
+		return nil, err
+	}
+	field := p.next()
+	if field.kind != tokIdent {
+		return nil, fmt.Errorf("expected field name, got %q", field.text)
+	}
+
+	op := p.next()
+	switch op.kind {
+	case tokEq, tokNeq:
+		val := p.next()
+		if val.kind != tokString {
+			return nil, fmt.Errorf("expected string after %q", op.text)
+		}
+		return predicateNode{
+			scope:  scope,
+			field:  field.text,
+			negate: op.kind == tokNeq,
+			values: []string{val.text},
+		}, nil
+	case tokIn:
+		if err := p.expect(tokLParen, "("); err != nil {
+			return nil, err
+		}
+		var values []string
+		for {
+			val := p.next()
+			if val.kind != tokString {
+				return nil, fmt.Errorf("expected string in \"in (...)\" list, got %q", val.text)
+			}
+			values = append(values, val.text)
+			if p.peek().kind == tokComma {
+				p.next()
+				continue
+			}
+			break
+		}
+		if err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return predicateNode{scope: scope, field: field.text, in: true, values: values}, nil
+	default:
+		return nil, fmt.Errorf("expected ==, != or in, got %q", op.text)
+	}
+}
+
+type exprTokenKind int
+
+const (
+	tokEOF exprTokenKind = iota
+	tokIdent
+	tokString
+	tokLParen
+	tokRParen
+	tokColon
+	tokComma
+	tokEq
+	tokNeq
+	tokAnd
+	tokOr
+	tokNot
+	tokIn
+)
+
+type exprToken struct {
+	kind exprTokenKind
+	text string
+}
+
+func lexExpr(s string) ([]exprToken, error) {
+	var toks []exprToken
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			toks = append(toks, exprToken{kind: tokLParen, text: "("})
+			i++
+		case c == ')':
+			toks = append(toks, exprToken{kind: tokRParen, text: ")"})
+			i++
+		case c == ':':
+			toks = append(toks, exprToken{kind: tokColon, text: ":"})
+			i++
+		case c == ',':
+			toks = append(toks, exprToken{kind: tokComma, text: ","})
+			i++
+		case c == '=' && i+1 < len(runes) && runes[i+1] == '=':
+			toks = append(toks, exprToken{kind: tokEq, text: "=="})
+			i += 2
+		case c == '!' && i+1 < len(runes) && runes[i+1] == '=':
+			toks = append(toks, exprToken{kind: tokNeq, text: "!="})
+			i += 2
+		case c == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				j++
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string starting at %d", i)
+			}
+			toks = append(toks, exprToken{kind: tokString, text: string(runes[i+1 : j])})
+			i = j + 1
+		case isIdentRune(c):
+			j := i + 1
+			for j < len(runes) && isIdentRune(runes[j]) {
+				j++
+			}
+			word := string(runes[i:j])
+			toks = append(toks, identToken(word))
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+		}
+	}
+
+	toks = append(toks, exprToken{kind: tokEOF})
+	return toks, nil
+}
+
+func identToken(word string) exprToken {
+	switch strings.ToLower(word) {
+	case "and":
+		return exprToken{kind: tokAnd, text: word}
+	case "or":
+		return exprToken{kind: tokOr, text: word}
+	case "not":
+		return exprToken{kind: tokNot, text: word}
+	case "in":
+		return exprToken{kind: tokIn, text: word}
+	default:
+		return exprToken{kind: tokIdent, text: word}
+	}
+}
+
+func isIdentRune(c rune) bool {
+	return c == '-' || c == '_' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
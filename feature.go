@@ -6,11 +6,15 @@
 // the default and only behavior
 //
 // Ways to enable a feature "paginate":
-//   - env:       X_FEATURE_PAGINATE=true|false
-//   - reqHeader: X-Feature-Paginate=true|false // case insensitive
-//   - reqQuery:    feature-paginate=true|false
+//   - env:       X_FEATURE_PAGINATE=true|false|25%
+//   - reqHeader: X-Feature-Paginate=true|false|25% // case insensitive
+//   - reqQuery:    feature-paginate=true|false|25%
 //
 // req header and query value will override env if defined
+//
+// A percentage value such as 25% gradually rolls a feature out to a
+// portion of traffic instead of turning it fully on or off; see
+// SetRollout.
 package feature
 
 import (
@@ -20,6 +24,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 const (
@@ -32,7 +37,25 @@ const (
 // if it is unchanged then obviously the default state is always disabled (false)
 // this state is used when the feature is not defined in the context at all
 // therefore any defined ctx state is preferred over this
-var defaultState = make(map[Feature]bool)
+//
+// it is guarded by a mutex because Enable/Disable can run concurrently
+// with IsEnabled under real request load
+var defaultState = struct {
+	mu    sync.RWMutex
+	state map[Feature]bool
+}{state: make(map[Feature]bool)}
+
+func getDefaultState(name Feature) bool {
+	defaultState.mu.RLock()
+	defer defaultState.mu.RUnlock()
+	return defaultState.state[name]
+}
+
+func setDefaultState(name Feature, enabled bool) {
+	defaultState.mu.Lock()
+	defer defaultState.mu.Unlock()
+	defaultState.state[name] = enabled
+}
 
 type Feature string
 
@@ -45,24 +68,59 @@ func (f Feature) String() string {
 
 func IsEnabled(ctx context.Context, name Feature) bool {
 	name = Feature(strings.ToLower(name.String()))
+	enabled, source := resolveEnabled(ctx, name)
+	fireHooks(name, enabled, source)
+	return enabled
+}
+
+// resolveEnabled runs IsEnabled's precedence chain (ctx > env > providers
+// > expr > rollout > default) without firing evaluation hooks or bumping
+// Metrics. It backs IsEnabled itself and expr.go's featureRefNode, so
+// evaluating a feature referenced from inside a RegisterExpr expression
+// doesn't inflate the metrics for that sub-feature.
+func resolveEnabled(ctx context.Context, name Feature) (bool, Source) {
 	if v := ctx.Value(name); v != nil {
-		return v.(bool)
+		switch val := v.(type) {
+		case percentOverride:
+			return evalRolloutPercent(ctx, name, val.percent, ""), SourceCtx
+		default:
+			return val.(bool), SourceCtx
+		}
+	}
+
+	if v, ok := envOverride(ctx, name); ok {
+		return v, SourceEnv
+	}
+
+	if v, ok := lookupProviders(name); ok {
+		return v, SourceProvider
+	}
+
+	if v, ok := evalExpr(ctx, name); ok {
+		return v, SourceExpr
 	}
 
-	if v, ok := isEnabledInEnv(name); ok {
-		return v
+	if v, ok := inRollout(ctx, name); ok {
+		return v, SourceRollout
 	}
 
-	return defaultState[name]
+	return getDefaultState(name), SourceDefault
 }
 
-func isEnabledInEnv(name Feature) (result bool, ok bool) {
+// envOverride reports the X_FEATURE_* override for name, if set. The
+// value is either a plain bool ("true"/"false") or a rollout percentage
+// ("25%").
+func envOverride(ctx context.Context, name Feature) (result bool, ok bool) {
 	key := EnvPrefix + strings.ToUpper(name.String())
-	if v := os.Getenv(key); v != "" {
-		result, _ = strconv.ParseBool(v)
-		return result, true
+	v := os.Getenv(key)
+	if v == "" {
+		return false, false
+	}
+	if percent, isPercent := parsePercent(v); isPercent {
+		return evalRolloutPercent(ctx, name, percent, ""), true
 	}
-	return false, false
+	result, _ = strconv.ParseBool(v)
+	return result, true
 }
 
 // Enable and Disable affect the global default state of the feature and should really only be used by tests
@@ -70,10 +128,10 @@ func isEnabledInEnv(name Feature) (result bool, ok bool) {
 // then the tests shouldn't call this anymore as it won't be needed
 // If defined in ctx, it will override this
 func Enable(name Feature) {
-	defaultState[name] = true
+	setDefaultState(name, true)
 }
 func Disable(name Feature) {
-	defaultState[name] = false
+	setDefaultState(name, false)
 }
 
 func EnableInCtx(ctx context.Context, f Feature) context.Context {
@@ -96,6 +154,7 @@ func ReqWithFeatureCtx(req *http.Request) *http.Request {
 
 	ctx = fromValues(ctx, req.URL.Query(), QueryPrefix)
 	ctx = fromValues(ctx, url.Values(req.Header), HeaderPrefix)
+	ctx = context.WithValue(ctx, headerValuesCtxKey{}, req.Header)
 
 	return req.WithContext(ctx)
 }
@@ -110,6 +169,10 @@ func fromValues(ctx context.Context, values url.Values, prefix string) context.C
 				trimKey = Feature(strings.TrimPrefix(lowerKey, prefix))
 				strVal  = values.Get(key)
 			)
+			if percent, ok := parsePercent(strVal); ok {
+				ctx = context.WithValue(ctx, trimKey, percentOverride{percent: percent})
+				continue
+			}
 			val, _ := strconv.ParseBool(strVal)
 			ctx = context.WithValue(ctx, trimKey, strVal == "" || val)
 		}
@@ -0,0 +1,155 @@
+// Package fileprovider implements feature.Provider backed by a local JSON
+// or YAML config file, hot-reloaded on change via fsnotify. It is meant
+// for small/single-node deployments that want centrally edited flags
+// without standing up a remote config service; see httpprovider for that.
+//
+// File format (JSON or YAML, by extension):
+//
+//	{
+//	  "features": {
+//	    "paginate": {"enabled": true, "rollout": 25}
+//	  }
+//	}
+//
+// A positive rollout registers the feature with feature.SetRollout on
+// load/reload, and Lookup defers to that rollout instead of answering
+// directly.
+package fileprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/tempcke/feature"
+	"gopkg.in/yaml.v3"
+)
+
+type entry struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	Rollout int  `json:"rollout" yaml:"rollout"`
+}
+
+type document struct {
+	Features map[string]entry `json:"features" yaml:"features"`
+}
+
+// Provider implements feature.Provider by reading a JSON or YAML config
+// file into memory and watching it for changes.
+type Provider struct {
+	path string
+
+	mu       sync.RWMutex
+	features map[feature.Feature]entry
+
+	watcher  *fsnotify.Watcher
+	done     chan struct{}
+	once     sync.Once
+	closeErr error
+}
+
+// New loads path and starts watching it for changes. Call Close when done
+// to stop the watcher goroutine.
+func New(path string) (*Provider, error) {
+	p := &Provider{path: path, done: make(chan struct{})}
+	if err := p.reload(); err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("fileprovider: new watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return nil, fmt.Errorf("fileprovider: watch %s: %w", path, err)
+	}
+	p.watcher = watcher
+
+	go p.watch()
+
+	return p, nil
+}
+
+// Lookup implements feature.Provider. An entry with a positive rollout percentage
+// defers to feature's own rollout handling (registered by reload via
+// feature.SetRollout) instead of answering directly, so bucketing stays
+// consistent with SetRollout called anywhere else.
+func (p *Provider) Lookup(name feature.Feature) (bool, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	e, ok := p.features[name]
+	if !ok || e.Rollout > 0 {
+		return false, false
+	}
+	return e.Enabled, true
+}
+
+// Close stops the background watcher goroutine. It is safe to call more
+// than once; only the first call has any effect.
+func (p *Provider) Close() error {
+	p.once.Do(func() {
+		close(p.done)
+		if p.watcher != nil {
+			p.closeErr = p.watcher.Close()
+		}
+	})
+	return p.closeErr
+}
+
+func (p *Provider) watch() {
+	for {
+		select {
+		case <-p.done:
+			return
+		case event, ok := <-p.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Name == p.path && (event.Op&(fsnotify.Write|fsnotify.Create) != 0) {
+				_ = p.reload()
+			}
+		case _, ok := <-p.watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+func (p *Provider) reload() error {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return fmt.Errorf("fileprovider: read %s: %w", p.path, err)
+	}
+
+	var doc document
+	if strings.HasSuffix(p.path, ".yaml") || strings.HasSuffix(p.path, ".yml") {
+		err = yaml.Unmarshal(data, &doc)
+	} else {
+		err = json.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return fmt.Errorf("fileprovider: parse %s: %w", p.path, err)
+	}
+
+	features := make(map[feature.Feature]entry, len(doc.Features))
+	for name, e := range doc.Features {
+		f := feature.Feature(strings.ToLower(name))
+		features[f] = e
+		if e.Rollout > 0 {
+			feature.SetRollout(f, e.Rollout, "")
+		}
+	}
+
+	p.mu.Lock()
+	p.features = features
+	p.mu.Unlock()
+
+	return nil
+}
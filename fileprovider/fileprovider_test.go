@@ -0,0 +1,88 @@
+package fileprovider_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tempcke/feature"
+	"github.com/tempcke/feature/fileprovider"
+)
+
+func TestProvider_Lookup(t *testing.T) {
+	var (
+		name = uuid.NewString()
+		path = writeFile(t, `{"features": {"`+name+`": {"enabled": true}}}`)
+	)
+
+	p, err := fileprovider.New(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = p.Close() })
+
+	v, ok := p.Lookup(feature.Feature(name))
+	require.True(t, ok)
+	assert.True(t, v)
+
+	_, ok = p.Lookup(feature.Feature(uuid.NewString()))
+	assert.False(t, ok, "an unconfigured feature should defer to the next source")
+}
+
+func TestProvider_ReloadsOnChange(t *testing.T) {
+	var (
+		name = uuid.NewString()
+		path = writeFile(t, `{"features": {"`+name+`": {"enabled": false}}}`)
+	)
+
+	p, err := fileprovider.New(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = p.Close() })
+
+	v, ok := p.Lookup(feature.Feature(name))
+	require.True(t, ok)
+	assert.False(t, v)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"features": {"`+name+`": {"enabled": true}}}`), 0o600))
+
+	require.Eventually(t, func() bool {
+		v, ok := p.Lookup(feature.Feature(name))
+		return ok && v
+	}, time.Second, 10*time.Millisecond, "provider should pick up the file change")
+}
+
+func TestProvider_RolloutDefersToFeatureRollout(t *testing.T) {
+	var (
+		name = uuid.NewString()
+		path = writeFile(t, `{"features": {"`+name+`": {"enabled": true, "rollout": 100}}}`)
+	)
+
+	p, err := fileprovider.New(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = p.Close() })
+
+	_, ok := p.Lookup(feature.Feature(name))
+	assert.False(t, ok, "a rollout entry should defer to feature.SetRollout, not answer directly")
+	assert.True(t, feature.IsEnabled(context.Background(), feature.Feature(name)),
+		"New should have registered the rollout via feature.SetRollout")
+}
+
+func TestProvider_CloseIsIdempotent(t *testing.T) {
+	path := writeFile(t, `{"features": {}}`)
+
+	p, err := fileprovider.New(path)
+	require.NoError(t, err)
+
+	assert.NoError(t, p.Close())
+	assert.NotPanics(t, func() { _ = p.Close() })
+}
+
+func writeFile(t testing.TB, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "features.json")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
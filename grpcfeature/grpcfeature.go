@@ -0,0 +1,139 @@
+// Package grpcfeature propagates feature toggles across gRPC calls the same
+// way feature.ReqWithFeatureCtx does for HTTP: server interceptors read
+// "x-feature-*" metadata off an incoming request into ctx, and client
+// interceptors copy feature values back out onto the outgoing request so a
+// toggle decided on one service still applies when it calls another.
+package grpcfeature
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/tempcke/feature"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// metadataPrefix mirrors feature.HeaderPrefix; gRPC metadata keys are
+// lowercased the same way HTTP header keys are.
+const metadataPrefix = feature.HeaderPrefix
+
+// UnaryServerInterceptor reads x-feature-* metadata off the incoming
+// context and makes it available to the handler via feature.IsEnabled.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{},
+		_ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		return handler(ctxFromIncomingMetadata(ctx), req)
+	}
+}
+
+// StreamServerInterceptor is the streaming equivalent of
+// UnaryServerInterceptor.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, ss grpc.ServerStream,
+		_ *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &serverStream{
+			ServerStream: ss,
+			ctx:          ctxFromIncomingMetadata(ss.Context()),
+		})
+	}
+}
+
+// UnaryClientInterceptor copies feature values set via
+// OutgoingCtxWithFeature into outgoing gRPC metadata before the call.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+	) error {
+		return invoker(ctxWithOutgoingMetadata(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor is the streaming equivalent of
+// UnaryClientInterceptor.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+		method string, streamer grpc.Streamer, opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		return streamer(ctxWithOutgoingMetadata(ctx), desc, cc, method, opts...)
+	}
+}
+
+// outgoingFeaturesCtxKey holds the map populated by OutgoingCtxWithFeature.
+type outgoingFeaturesCtxKey struct{}
+
+// OutgoingCtxWithFeature marks f as enabled/disabled for every outgoing
+// gRPC call made with the returned ctx. The client interceptors copy this onto the
+// gRPC metadata; it has no effect without them installed.
+func OutgoingCtxWithFeature(ctx context.Context, f feature.Feature, enabled bool) context.Context {
+	existing, _ := ctx.Value(outgoingFeaturesCtxKey{}).(map[feature.Feature]bool)
+
+	next := make(map[feature.Feature]bool, len(existing)+1)
+	for k, v := range existing {
+		next[k] = v
+	}
+	next[f] = enabled
+
+	return context.WithValue(ctx, outgoingFeaturesCtxKey{}, next)
+}
+
+func ctxWithOutgoingMetadata(ctx context.Context) context.Context {
+	features, _ := ctx.Value(outgoingFeaturesCtxKey{}).(map[feature.Feature]bool)
+	if len(features) == 0 {
+		return ctx
+	}
+
+	pairs := make([]string, 0, len(features)*2)
+	for f, enabled := range features {
+		pairs = append(pairs, metadataPrefix+f.String(), strconv.FormatBool(enabled))
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// IncomingCtxFeatures returns every x-feature-* value found in the
+// incoming gRPC metadata of ctx.
+func IncomingCtxFeatures(ctx context.Context) map[feature.Feature]bool {
+	out := make(map[feature.Feature]bool)
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return out
+	}
+
+	for key, values := range md {
+		if len(values) == 0 || !strings.HasPrefix(key, metadataPrefix) {
+			continue
+		}
+		name := feature.Feature(strings.TrimPrefix(key, metadataPrefix))
+		enabled, _ := strconv.ParseBool(values[0])
+		out[name] = enabled
+	}
+	return out
+}
+
+func ctxFromIncomingMetadata(ctx context.Context) context.Context {
+	for name, enabled := range IncomingCtxFeatures(ctx) {
+		if enabled {
+			ctx = feature.EnableInCtx(ctx, name)
+		} else {
+			ctx = feature.DisableInCtx(ctx, name)
+		}
+	}
+	return ctx
+}
+
+// serverStream wraps grpc.ServerStream to override Context, the same
+// pattern grpc-ecosystem/go-grpc-middleware uses for stream interceptors.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
@@ -0,0 +1,111 @@
+package grpcfeature_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tempcke/feature"
+	"github.com/tempcke/feature/grpcfeature"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestIncomingCtxFeatures(t *testing.T) {
+	name := feature.Feature(uuid.NewString())
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"x-feature-"+name.String(), "true",
+	))
+
+	got := grpcfeature.IncomingCtxFeatures(ctx)
+	assert.Equal(t, map[feature.Feature]bool{name: true}, got)
+}
+
+func TestUnaryServerInterceptor_MakesIncomingFeatureAvailable(t *testing.T) {
+	name := feature.Feature(uuid.NewString())
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"x-feature-"+name.String(), "true",
+	))
+
+	interceptor := grpcfeature.UnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return feature.IsEnabled(ctx, name), nil
+	}
+
+	resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	assert.True(t, resp.(bool))
+}
+
+func TestStreamServerInterceptor_MakesIncomingFeatureAvailable(t *testing.T) {
+	name := feature.Feature(uuid.NewString())
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"x-feature-"+name.String(), "false",
+	))
+
+	interceptor := grpcfeature.StreamServerInterceptor()
+	var seenCtx context.Context
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		seenCtx = ss.Context()
+		return nil
+	}
+
+	err := interceptor(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{}, handler)
+	require.NoError(t, err)
+	assert.False(t, feature.IsEnabled(seenCtx, name))
+}
+
+func TestUnaryClientInterceptor_SendsOutgoingFeatures(t *testing.T) {
+	name := feature.Feature(uuid.NewString())
+	ctx := grpcfeature.OutgoingCtxWithFeature(context.Background(), name, true)
+
+	interceptor := grpcfeature.UnaryClientInterceptor()
+	var seenCtx context.Context
+	invoker := func(
+		ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, opts ...grpc.CallOption,
+	) error {
+		seenCtx = ctx
+		return nil
+	}
+
+	err := interceptor(ctx, "/svc/Method", nil, nil, nil, invoker)
+	require.NoError(t, err)
+
+	md, ok := metadata.FromOutgoingContext(seenCtx)
+	require.True(t, ok)
+	assert.Equal(t, []string{"true"}, md.Get("x-feature-"+name.String()))
+}
+
+func TestStreamClientInterceptor_SendsOutgoingFeatures(t *testing.T) {
+	name := feature.Feature(uuid.NewString())
+	ctx := grpcfeature.OutgoingCtxWithFeature(context.Background(), name, false)
+
+	interceptor := grpcfeature.StreamClientInterceptor()
+	var seenCtx context.Context
+	streamer := func(
+		ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+		method string, opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		seenCtx = ctx
+		return nil, nil
+	}
+
+	_, err := interceptor(ctx, &grpc.StreamDesc{}, nil, "/svc/Method", streamer)
+	require.NoError(t, err)
+
+	md, ok := metadata.FromOutgoingContext(seenCtx)
+	require.True(t, ok)
+	assert.Equal(t, []string{"false"}, md.Get("x-feature-"+name.String()))
+}
+
+// fakeServerStream implements grpc.ServerStream with only Context overridden,
+// the same pattern grpcfeature's own serverStream wrapper uses.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
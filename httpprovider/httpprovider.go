@@ -0,0 +1,149 @@
+// Package httpprovider implements feature.Provider backed by a document
+// periodically polled over HTTP, for teams that manage flags through a
+// central service rather than a file deployed alongside the binary.
+//
+// The polled document looks like:
+//
+//	{"features": {"paginate": {"enabled": true, "rollout": 25}}}
+//
+// A positive rollout registers the feature with feature.SetRollout on
+// each poll, and Lookup defers to that rollout instead of answering
+// directly.
+package httpprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tempcke/feature"
+)
+
+type entry struct {
+	Enabled bool `json:"enabled"`
+	Rollout int  `json:"rollout"`
+}
+
+type document struct {
+	Features map[string]entry `json:"features"`
+}
+
+// Provider implements feature.Provider by polling url on an interval,
+// caching the last successful response and using ETag/If-None-Match to
+// avoid re-fetching an unchanged document.
+type Provider struct {
+	url    string
+	client *http.Client
+
+	mu       sync.RWMutex
+	features map[feature.Feature]entry
+	etag     string
+
+	done chan struct{}
+	once sync.Once
+}
+
+// New starts polling url every interval. Call Close to stop polling.
+func New(url string, interval time.Duration) (*Provider, error) {
+	p := &Provider{
+		url:    url,
+		client: http.DefaultClient,
+		done:   make(chan struct{}),
+	}
+
+	if err := p.poll(); err != nil {
+		return nil, err
+	}
+
+	go p.loop(interval)
+
+	return p, nil
+}
+
+// Lookup implements feature.Provider. An entry with a positive rollout percentage
+// defers to feature's own rollout handling (registered by poll via
+// feature.SetRollout) instead of answering directly, so bucketing stays
+// consistent with SetRollout called anywhere else.
+func (p *Provider) Lookup(name feature.Feature) (bool, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	e, ok := p.features[name]
+	if !ok || e.Rollout > 0 {
+		return false, false
+	}
+	return e.Enabled, true
+}
+
+// Close stops the background polling goroutine. It is safe to call more
+// than once; only the first call has any effect.
+func (p *Provider) Close() error {
+	p.once.Do(func() { close(p.done) })
+	return nil
+}
+
+func (p *Provider) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			_ = p.poll()
+		}
+	}
+}
+
+func (p *Provider) poll() error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, p.url, nil)
+	if err != nil {
+		return fmt.Errorf("httpprovider: build request: %w", err)
+	}
+
+	p.mu.RLock()
+	etag := p.etag
+	p.mu.RUnlock()
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("httpprovider: fetch %s: %w", p.url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("httpprovider: fetch %s: status %s", p.url, resp.Status)
+	}
+
+	var doc document
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("httpprovider: decode %s: %w", p.url, err)
+	}
+
+	features := make(map[feature.Feature]entry, len(doc.Features))
+	for name, e := range doc.Features {
+		f := feature.Feature(strings.ToLower(name))
+		features[f] = e
+		if e.Rollout > 0 {
+			feature.SetRollout(f, e.Rollout, "")
+		}
+	}
+
+	p.mu.Lock()
+	p.features = features
+	p.etag = resp.Header.Get("ETag")
+	p.mu.Unlock()
+
+	return nil
+}
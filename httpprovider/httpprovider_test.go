@@ -0,0 +1,94 @@
+package httpprovider_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tempcke/feature"
+	"github.com/tempcke/feature/httpprovider"
+)
+
+func TestProvider_Lookup(t *testing.T) {
+	name := uuid.NewString()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"features": map[string]any{name: map[string]any{"enabled": true}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	p, err := httpprovider.New(srv.URL, time.Hour)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = p.Close() })
+
+	v, ok := p.Lookup(feature.Feature(name))
+	require.True(t, ok)
+	assert.True(t, v)
+}
+
+func TestProvider_UsesETagToAvoidRefetching(t *testing.T) {
+	var requests, fullFetches atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fullFetches.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		_ = json.NewEncoder(w).Encode(map[string]any{"features": map[string]any{}})
+	}))
+	t.Cleanup(srv.Close)
+
+	p, err := httpprovider.New(srv.URL, 10*time.Millisecond)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = p.Close() })
+
+	require.Eventually(t, func() bool { return requests.Load() >= 3 }, time.Second, 5*time.Millisecond)
+
+	// every poll after the first should have sent the ETag back
+	assert.GreaterOrEqual(t, int(requests.Load()), 3)
+	assert.Equal(t, int32(1), fullFetches.Load())
+}
+
+func TestProvider_RolloutDefersToFeatureRollout(t *testing.T) {
+	name := uuid.NewString()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"features": map[string]any{name: map[string]any{"enabled": true, "rollout": 100}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	p, err := httpprovider.New(srv.URL, time.Hour)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = p.Close() })
+
+	_, ok := p.Lookup(feature.Feature(name))
+	assert.False(t, ok, "a rollout entry should defer to feature.SetRollout, not answer directly")
+	assert.True(t, feature.IsEnabled(context.Background(), feature.Feature(name)),
+		"New should have registered the rollout via feature.SetRollout")
+}
+
+func TestProvider_CloseIsIdempotent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"features": map[string]any{}})
+	}))
+	t.Cleanup(srv.Close)
+
+	p, err := httpprovider.New(srv.URL, time.Hour)
+	require.NoError(t, err)
+
+	assert.NoError(t, p.Close())
+	assert.NotPanics(t, func() { _ = p.Close() })
+}
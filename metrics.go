@@ -0,0 +1,102 @@
+package feature
+
+import (
+	"sort"
+	"sync"
+)
+
+// Source identifies which part of IsEnabled's precedence chain decided a
+// feature's state.
+type Source int
+
+const (
+	SourceCtx Source = iota
+	SourceEnv
+	SourceProvider
+	SourceExpr
+	SourceRollout
+	SourceDefault
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceCtx:
+		return "ctx"
+	case SourceEnv:
+		return "env"
+	case SourceProvider:
+		return "provider"
+	case SourceExpr:
+		return "expr"
+	case SourceRollout:
+		return "rollout"
+	default:
+		return "default"
+	}
+}
+
+// EvalHook is called by IsEnabled every time it resolves a feature's
+// state, so operators can see which toggles are actually being hit in
+// production and from where.
+type EvalHook func(name Feature, enabled bool, source Source)
+
+var (
+	hooksMu sync.RWMutex
+	hooks   []EvalHook
+)
+
+// OnEvaluate registers fn to be called on every IsEnabled evaluation.
+func OnEvaluate(fn EvalHook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, fn)
+}
+
+type metricKey struct {
+	name   Feature
+	source Source
+}
+
+var (
+	metricsMu sync.Mutex
+	counts    = make(map[metricKey]uint64)
+)
+
+func fireHooks(name Feature, enabled bool, source Source) {
+	metricsMu.Lock()
+	counts[metricKey{name: name, source: source}]++
+	metricsMu.Unlock()
+
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	for _, h := range hooks {
+		h(name, enabled, source)
+	}
+}
+
+// FeatureMetric is one row of the Metrics snapshot: how many times name
+// was evaluated and resolved via source.
+type FeatureMetric struct {
+	Name   Feature
+	Source Source
+	Count  uint64
+}
+
+// Metrics returns a snapshot of evaluation counts per feature and source,
+// sorted by name then source.
+func Metrics() []FeatureMetric {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	out := make([]FeatureMetric, 0, len(counts))
+	for k, c := range counts {
+		out = append(out, FeatureMetric{Name: k.name, Source: k.source, Count: c})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].Source < out[j].Source
+	})
+	return out
+}
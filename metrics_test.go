@@ -0,0 +1,53 @@
+package feature_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tempcke/feature"
+)
+
+func countFor(name feature.Feature, source feature.Source) uint64 {
+	for _, m := range feature.Metrics() {
+		if m.Name == name && m.Source == source {
+			return m.Count
+		}
+	}
+	return 0
+}
+
+func TestMetrics_DistinguishesRolloutAndExprFromDefault(t *testing.T) {
+	rollout := feature.Feature(uuid.NewString())
+	feature.SetRollout(rollout, 100, "")
+	feature.IsEnabled(context.Background(), rollout)
+	assert.Equal(t, uint64(1), countFor(rollout, feature.SourceRollout))
+	assert.Equal(t, uint64(0), countFor(rollout, feature.SourceDefault))
+
+	base := feature.Feature(uuid.NewString())
+	feature.Enable(base)
+	composed := feature.Feature(uuid.NewString())
+	require.NoError(t, feature.RegisterExpr(composed, base.String()))
+	feature.IsEnabled(context.Background(), composed)
+	assert.Equal(t, uint64(1), countFor(composed, feature.SourceExpr))
+	assert.Equal(t, uint64(0), countFor(composed, feature.SourceDefault))
+
+	def := feature.Feature(uuid.NewString())
+	feature.IsEnabled(context.Background(), def)
+	assert.Equal(t, uint64(1), countFor(def, feature.SourceDefault))
+}
+
+func TestMetrics_SubEvaluationsOfComposedFeaturesAreNotCounted(t *testing.T) {
+	base := feature.Feature(uuid.NewString())
+	feature.Enable(base)
+	composed := feature.Feature(uuid.NewString())
+	require.NoError(t, feature.RegisterExpr(composed, base.String()))
+
+	before := countFor(base, feature.SourceDefault)
+	feature.IsEnabled(context.Background(), composed)
+
+	assert.Equal(t, before, countFor(base, feature.SourceDefault),
+		"evaluating composed should not also bump metrics for the feature it references")
+}
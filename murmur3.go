@@ -0,0 +1,55 @@
+package feature
+
+// murmur3_32 is a minimal, dependency-free implementation of the 32-bit
+// x86 variant of MurmurHash3, used to deterministically bucket stickiness
+// values for rollout percentages. It is not intended for anything beyond
+// that; reach for a vetted hashing library if you need murmur3 elsewhere.
+func murmur3_32(key string, seed uint32) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+
+	var (
+		h      = seed
+		length = len(key)
+		nblock = length / 4
+	)
+
+	for i := 0; i < nblock; i++ {
+		k := uint32(key[i*4]) | uint32(key[i*4+1])<<8 | uint32(key[i*4+2])<<16 | uint32(key[i*4+3])<<24
+		k *= c1
+		k = (k << 15) | (k >> 17)
+		k *= c2
+
+		h ^= k
+		h = (h << 13) | (h >> 19)
+		h = h*5 + 0xe6546b64
+	}
+
+	var k uint32
+	tail := key[nblock*4:]
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = (k << 15) | (k >> 17)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(length)
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+
+	return h
+}
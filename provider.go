@@ -0,0 +1,38 @@
+package feature
+
+import "sync"
+
+// Provider sources feature state from somewhere other than ctx, env, or
+// the in-process default state, e.g. a config file or a centrally managed
+// flag service.
+type Provider interface {
+	// Lookup returns the enabled state for name, and whether the provider
+	// has an opinion about it at all. ok=false defers to the next source
+	// in IsEnabled's precedence.
+	Lookup(name Feature) (value bool, ok bool)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   []Provider
+)
+
+// RegisterProvider adds p to the providers consulted by IsEnabled, in
+// registration order, after env overrides and before rollout/default
+// state. Providers must be safe for concurrent use.
+func RegisterProvider(p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers = append(providers, p)
+}
+
+func lookupProviders(name Feature) (bool, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	for _, p := range providers {
+		if v, ok := p.Lookup(name); ok {
+			return v, true
+		}
+	}
+	return false, false
+}
@@ -0,0 +1,233 @@
+package feature
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Variant is one arm of an A/B/n rollout for a feature that has been
+// registered with RegisterVariant.
+//
+// FeatureEnabled reports whether the feature itself is on, independent of
+// whether a variant was selected. Enabled reports whether this particular
+// variant represents the "on" behavior, which lets a variant opt a request
+// into a control/placebo arm while the feature is still considered enabled.
+type Variant struct {
+	Name           string
+	Enabled        bool
+	FeatureEnabled bool
+	Payload        Payload
+}
+
+// Payload is an arbitrary typed value attached to a Variant, e.g.
+// Payload{Type: "int", Value: "25"}.
+type Payload struct {
+	Type  string
+	Value string
+}
+
+// noVariant is returned by GetVariant when the feature is enabled but has
+// no variants registered, so callers can distinguish "off" from
+// "on but no variant".
+var noVariant = Variant{FeatureEnabled: true}
+
+// FeatureInfo describes a registered feature for introspection via List.
+// Expr is set instead of DefaultEnabled/Variants for a feature defined
+// via RegisterExpr, since its state is computed rather than stored.
+type FeatureInfo struct {
+	Name           Feature
+	Description    string
+	DefaultEnabled bool
+	Variants       []string
+	Expr           string
+}
+
+type weightedVariant struct {
+	variant Variant
+	weight  int
+}
+
+type registryEntry struct {
+	description    string
+	defaultEnabled bool
+	variants       []weightedVariant
+}
+
+// registry holds features declared via Register/RegisterVariant. It is
+// guarded by a mutex because Register/RegisterVariant can run concurrently
+// with GetVariant/List under real request load, same as defaultState.
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[Feature]*registryEntry)
+)
+
+// RegisterOption configures optional metadata on Register.
+type RegisterOption func(*registryEntry)
+
+// WithDescription attaches a human-readable description to a registered
+// feature, surfaced later by List.
+func WithDescription(desc string) RegisterOption {
+	return func(e *registryEntry) { e.description = desc }
+}
+
+// Register declares a feature up front with a default state and optional
+// metadata. It also sets the global default state, the same as calling
+// Enable/Disable would, so IsEnabled behaves exactly as it does for a
+// feature that was never registered.
+func Register(name Feature, defaultEnabled bool, opts ...RegisterOption) {
+	name = Feature(strings.ToLower(name.String()))
+
+	registryMu.Lock()
+	entry := entryForLocked(name)
+	entry.defaultEnabled = defaultEnabled
+	for _, opt := range opts {
+		opt(entry)
+	}
+	registryMu.Unlock()
+
+	if defaultEnabled {
+		Enable(name)
+	} else {
+		Disable(name)
+	}
+}
+
+// RegisterVariant declares the variants a feature can resolve to once it is
+// enabled. weights must either be omitted (variants are weighted equally)
+// or have the same length as variants.
+func RegisterVariant(name Feature, variants []Variant, weights ...int) {
+	name = Feature(strings.ToLower(name.String()))
+
+	if len(weights) != 0 && len(weights) != len(variants) {
+		panic("feature: RegisterVariant: weights must match variants length")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	entry := entryForLocked(name)
+	entry.variants = entry.variants[:0]
+	for i, v := range variants {
+		weight := 1
+		if len(weights) != 0 {
+			weight = weights[i]
+		}
+		entry.variants = append(entry.variants, weightedVariant{variant: v, weight: weight})
+	}
+}
+
+// entryForLocked returns name's registry entry, creating it if absent.
+// Callers must hold registryMu.
+func entryForLocked(name Feature) *registryEntry {
+	entry, ok := registry[name]
+	if !ok {
+		entry = &registryEntry{}
+		registry[name] = entry
+	}
+	return entry
+}
+
+// stickinessIDCtxKey is the ctx key under which WithStickinessID stores its
+// value.
+type stickinessIDCtxKey struct{}
+
+// WithStickinessID stores id in ctx as the value GetVariant hashes on to
+// deterministically pick a variant for a request, e.g. a user ID so the
+// same user keeps landing in the same variant across requests.
+func WithStickinessID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, stickinessIDCtxKey{}, id)
+}
+
+func stickinessID(ctx context.Context) string {
+	id, _ := ctx.Value(stickinessIDCtxKey{}).(string)
+	return id
+}
+
+// GetVariant resolves which Variant a request falls into for name.
+//
+// If the feature is not enabled (per IsEnabled, with the same precedence
+// chain), it returns the zero Variant. If the feature is
+// enabled but has no variants registered, it returns a sentinel
+// {FeatureEnabled: true} so callers can tell "off" apart from
+// "on but no variant". Otherwise selection is deterministic per request,
+// based on a hash of the stickiness ID in ctx (see WithStickinessID) and
+// the variant weights.
+func GetVariant(ctx context.Context, name Feature) Variant {
+	name = Feature(strings.ToLower(name.String()))
+
+	if !IsEnabled(ctx, name) {
+		return Variant{}
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	entry, ok := registry[name]
+	if !ok || len(entry.variants) == 0 {
+		return noVariant
+	}
+
+	total := 0
+	for _, wv := range entry.variants {
+		total += wv.weight
+	}
+	if total <= 0 {
+		return noVariant
+	}
+
+	bucket := int(hashString(stickinessID(ctx)+":"+name.String()) % uint32(total))
+	for _, wv := range entry.variants {
+		bucket -= wv.weight
+		if bucket < 0 {
+			variant := wv.variant
+			variant.FeatureEnabled = true
+			return variant
+		}
+	}
+
+	// unreachable in practice: total was computed from the same weights
+	return noVariant
+}
+
+func hashString(s string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum32()
+}
+
+// List returns introspection info for every feature registered via
+// Register or RegisterExpr, sorted by name.
+func List() []FeatureInfo {
+	registryMu.RLock()
+	out := make([]FeatureInfo, 0, len(registry))
+	seen := make(map[Feature]bool, len(registry))
+	for name, entry := range registry {
+		variantNames := make([]string, 0, len(entry.variants))
+		for _, wv := range entry.variants {
+			variantNames = append(variantNames, wv.variant.Name)
+		}
+		out = append(out, FeatureInfo{
+			Name:           name,
+			Description:    entry.description,
+			DefaultEnabled: entry.defaultEnabled,
+			Variants:       variantNames,
+		})
+		seen[name] = true
+	}
+	registryMu.RUnlock()
+
+	exprMu.RLock()
+	for name, e := range exprs {
+		if seen[name] {
+			continue
+		}
+		out = append(out, FeatureInfo{Name: name, Expr: e.raw})
+	}
+	exprMu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
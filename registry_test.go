@@ -0,0 +1,93 @@
+package feature_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tempcke/feature"
+)
+
+func TestRegister_SetsDefaultStateAndDescription(t *testing.T) {
+	name := feature.Feature(uuid.NewString())
+	feature.Register(name, true, feature.WithDescription("used by checkout"))
+
+	assert.True(t, feature.IsEnabled(context.Background(), name))
+
+	info := findFeature(t, name)
+	assert.True(t, info.DefaultEnabled)
+	assert.Equal(t, "used by checkout", info.Description)
+}
+
+func TestGetVariant_FeatureOffReturnsZeroValue(t *testing.T) {
+	name := feature.Feature(uuid.NewString())
+	feature.Disable(name)
+
+	assert.Equal(t, feature.Variant{}, feature.GetVariant(context.Background(), name))
+}
+
+func TestGetVariant_EnabledWithNoVariantsReturnsSentinel(t *testing.T) {
+	name := feature.Feature(uuid.NewString())
+	feature.Enable(name)
+
+	v := feature.GetVariant(context.Background(), name)
+	assert.True(t, v.FeatureEnabled)
+	assert.Empty(t, v.Name)
+}
+
+func TestGetVariant_IsStickyPerID(t *testing.T) {
+	name := feature.Feature(uuid.NewString())
+	feature.Enable(name)
+	feature.RegisterVariant(name, []feature.Variant{
+		{Name: "control", Enabled: false},
+		{Name: "treatment", Enabled: true},
+	})
+
+	ctx := feature.WithStickinessID(context.Background(), "user-123")
+	first := feature.GetVariant(ctx, name)
+	require.NotEmpty(t, first.Name)
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first.Name, feature.GetVariant(ctx, name).Name)
+	}
+}
+
+func TestGetVariant_WeightsControlSelection(t *testing.T) {
+	name := feature.Feature(uuid.NewString())
+	feature.Enable(name)
+	feature.RegisterVariant(name, []feature.Variant{
+		{Name: "control", Enabled: false},
+		{Name: "treatment", Enabled: true},
+	}, 100, 0)
+
+	for i := 0; i < 20; i++ {
+		ctx := feature.WithStickinessID(context.Background(), uuid.NewString())
+		v := feature.GetVariant(ctx, name)
+		assert.Equal(t, "control", v.Name)
+		assert.True(t, v.FeatureEnabled)
+	}
+}
+
+func TestList_IncludesRegisteredFeature(t *testing.T) {
+	name := feature.Feature(uuid.NewString())
+	feature.Register(name, true, feature.WithDescription("desc"))
+	feature.RegisterVariant(name, []feature.Variant{{Name: "a"}, {Name: "b"}})
+
+	info := findFeature(t, name)
+	assert.True(t, info.DefaultEnabled)
+	assert.Equal(t, "desc", info.Description)
+	assert.ElementsMatch(t, []string{"a", "b"}, info.Variants)
+}
+
+func findFeature(t testing.TB, name feature.Feature) feature.FeatureInfo {
+	t.Helper()
+	for _, info := range feature.List() {
+		if info.Name == name {
+			return info
+		}
+	}
+	t.Fatalf("feature %q not found in List()", name)
+	return feature.FeatureInfo{}
+}
@@ -0,0 +1,118 @@
+package feature_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tempcke/feature"
+)
+
+// stubHasher lets rollout bucketing be made predictable in tests, per the
+// Hasher doc comment.
+type stubHasher struct{ bucket uint32 }
+
+func (h stubHasher) Hash(string) uint32 { return h.bucket }
+
+func TestSetRollout(t *testing.T) {
+	t.Run("bucket below percent is enabled", func(t *testing.T) {
+		feature.SetHasher(stubHasher{bucket: 10})
+
+		var (
+			f   = feature.Feature(uuid.NewString())
+			ctx = feature.WithStickinessKey(context.Background(), "userID", "u1")
+		)
+		feature.SetRollout(f, 50, "userID")
+
+		assert.True(t, feature.IsEnabled(ctx, f))
+	})
+
+	t.Run("bucket at or above percent is disabled", func(t *testing.T) {
+		feature.SetHasher(stubHasher{bucket: 90})
+
+		var (
+			f   = feature.Feature(uuid.NewString())
+			ctx = feature.WithStickinessKey(context.Background(), "userID", "u1")
+		)
+		feature.SetRollout(f, 50, "userID")
+
+		assert.False(t, feature.IsEnabled(ctx, f))
+	})
+
+	t.Run("ctx override still wins over rollout", func(t *testing.T) {
+		feature.SetHasher(stubHasher{bucket: 0}) // would otherwise always enable
+
+		var (
+			f   = feature.Feature(uuid.NewString())
+			ctx = feature.WithStickinessKey(context.Background(), "userID", "u1")
+		)
+		feature.SetRollout(f, 100, "userID")
+
+		assert.False(t, feature.IsEnabled(feature.DisableInCtx(ctx, f), f))
+	})
+
+	t.Run("percent above 100 is clamped so it can't be permanently on", func(t *testing.T) {
+		feature.SetHasher(stubHasher{bucket: 99})
+
+		var (
+			f   = feature.Feature(uuid.NewString())
+			ctx = feature.WithStickinessKey(context.Background(), "userID", "u1")
+		)
+		feature.SetRollout(f, 150, "userID")
+
+		assert.True(t, feature.IsEnabled(ctx, f), "150%% should clamp to 100%%, not disable everything")
+	})
+
+	t.Run("negative percent is clamped so it can't be permanently off", func(t *testing.T) {
+		feature.SetHasher(stubHasher{bucket: 0})
+
+		var (
+			f   = feature.Feature(uuid.NewString())
+			ctx = feature.WithStickinessKey(context.Background(), "userID", "u1")
+		)
+		feature.SetRollout(f, -5, "userID")
+
+		assert.False(t, feature.IsEnabled(ctx, f))
+	})
+}
+
+func TestEnvRolloutPercent(t *testing.T) {
+	feature.SetHasher(stubHasher{bucket: 10})
+	var (
+		f      = feature.Feature(uuid.NewString())
+		envKey = feature.EnvPrefix + strings.ToUpper(f.String())
+	)
+
+	t.Run("env percent over 100 is clamped", func(t *testing.T) {
+		t.Setenv(envKey, "999%")
+		assert.True(t, feature.IsEnabled(context.Background(), f))
+	})
+
+	t.Run("env percent under 0 is clamped", func(t *testing.T) {
+		t.Setenv(envKey, "-999%")
+		assert.False(t, feature.IsEnabled(context.Background(), f))
+	})
+}
+
+func TestReqWithFeatureCtxRolloutPercent(t *testing.T) {
+	feature.SetHasher(stubHasher{bucket: 10})
+	f := uuid.NewString()
+	req := newReqWithQuery(t, feature.QueryPrefix+f, "150%")
+	req = feature.ReqWithFeatureCtx(req)
+
+	assert.True(t, feature.IsEnabled(req.Context(), feature.Feature(f)),
+		"a >100%% query override should clamp to 100%%, not be dropped")
+}
+
+func newReqWithQuery(t testing.TB, key, val string) *http.Request {
+	t.Helper()
+	query := url.Values{key: {val}}.Encode()
+	req, err := http.NewRequest(http.MethodGet, "https://example.com?"+query, nil)
+	require.NoError(t, err)
+	return req
+}